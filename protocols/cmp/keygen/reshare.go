@@ -0,0 +1,466 @@
+package keygen
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+
+	"github.com/cronokirby/safenum"
+	"github.com/taurusgroup/cmp-ecdsa/internal/proto"
+	"github.com/taurusgroup/cmp-ecdsa/internal/round"
+	"github.com/taurusgroup/cmp-ecdsa/pkg/math/curve"
+	"github.com/taurusgroup/cmp-ecdsa/pkg/math/polynomial"
+	"github.com/taurusgroup/cmp-ecdsa/pkg/paillier"
+	"github.com/taurusgroup/cmp-ecdsa/pkg/party"
+	"github.com/taurusgroup/cmp-ecdsa/pkg/protocol/message"
+	"github.com/taurusgroup/cmp-ecdsa/pkg/protocol/types"
+	zkmod "github.com/taurusgroup/cmp-ecdsa/pkg/zk/mod"
+	zkprm "github.com/taurusgroup/cmp-ecdsa/pkg/zk/prm"
+	zksch "github.com/taurusgroup/cmp-ecdsa/pkg/zk/sch"
+)
+
+// Errors returned while running the TShare resharing protocol.
+var (
+	ErrReshareThreshold           = errors.New("keygen.reshare: new threshold must be smaller than the number of new parties")
+	ErrReshareZKMod               = errors.New("keygen.reshare: failed to verify zkmod proof")
+	ErrReshareZKPrm               = errors.New("keygen.reshare: failed to verify zkprm proof")
+	ErrReshareDecrypt             = errors.New("keygen.reshare: failed to decrypt VSS share")
+	ErrReshareVSS                 = errors.New("keygen.reshare: share does not match the sender's VSS commitment")
+	ErrReshareNotEnoughOldSigners = errors.New("keygen.reshare: fewer than OldConfig.Threshold+1 old signers contributed a VSS share")
+	ErrReshareVSSMismatch         = errors.New("keygen.reshare: Lagrange-weighted VSS commitments do not interpolate to the original public key")
+	ErrReshareSchnorr             = errors.New("keygen.reshare: failed to verify Schnorr proof of the new share")
+	ErrReshareNewShareMismatch    = errors.New("keygen.reshare: new committee member's public share does not match the Lagrange-weighted VSS commitments")
+)
+
+// StartReshare returns the first round of the TShare protocol.
+//
+// oldConfig is the caller's current Config. newPartyIDs and newThreshold describe
+// the committee that should hold the resulting shares. Only old signers that are
+// also in newPartyIDs contribute, so the overlap with oldConfig.PartyIDs() must
+// hold at least oldConfig.Threshold+1 parties or round3 returns
+// ErrReshareNotEnoughOldSigners; newThreshold may be smaller or larger than
+// oldConfig.Threshold. The resulting Config preserves the group's ECDSA public key:
+// this is a re-sharing of the same secret, not a fresh keygen.
+//
+// source is used for every randomness draw made over the course of the protocol —
+// polynomial sampling, Paillier/ring-Pedersen generation, and Schnorr nonces. A nil
+// source falls back to crypto/rand.Reader.
+func StartReshare(oldConfig *Config, newPartyIDs party.IDSlice, newThreshold int, source io.Reader) (round.Round, error) {
+	if newThreshold < 0 || newThreshold >= len(newPartyIDs) {
+		return nil, ErrReshareThreshold
+	}
+	if source == nil {
+		source = rand.Reader
+	}
+
+	contributing := make(party.IDSlice, 0, len(oldConfig.PartyIDs()))
+	for _, id := range oldConfig.PartyIDs() {
+		if newPartyIDs.Contains(id) {
+			contributing = append(contributing, id)
+		}
+	}
+
+	helper, err := round.NewHelper(oldConfig.SSID(), newPartyIDs, source)
+	if err != nil {
+		return nil, err
+	}
+
+	return &reshareRound1{
+		Helper:       helper,
+		OldConfig:    oldConfig,
+		NewPartyIDs:  newPartyIDs,
+		NewThreshold: newThreshold,
+		Contributing: contributing,
+	}, nil
+}
+
+// reshareRound1 is the entry point: nothing has been received yet, so its only
+// job is to mint this party's contribution to the new committee's public
+// parameters and send it off.
+type reshareRound1 struct {
+	*round.Helper
+
+	OldConfig    *Config
+	NewPartyIDs  party.IDSlice
+	NewThreshold int
+	// Contributing = P ∩ P', the old signers who must re-share their existing share.
+	Contributing party.IDSlice
+}
+
+func (r *reshareRound1) ProcessMessage(party.ID, message.Content) error { return nil }
+
+// Finalize implements round.Round.
+//
+// Every member of the new committee mints a fresh Paillier key and ring-Pedersen
+// parameters, exactly as keygen's own round1 through round3 would, and proves them
+// well formed with zkmod/zkprm — there's no reason to trust a recycled modulus
+// across resharings.
+func (r *reshareRound1) Finalize(out chan<- *message.Message) (round.Round, error) {
+	next := &reshareRound2{
+		reshareRound1: r,
+		N:             map[party.ID]*safenum.Modulus{},
+		S:             map[party.ID]*safenum.Nat{},
+		T:             map[party.ID]*safenum.Nat{},
+		RID:           map[party.ID]RID{},
+	}
+
+	if !r.NewPartyIDs.Contains(r.SelfID()) {
+		return next, nil
+	}
+
+	paillierSecret := paillier.NewSecretKey(r.Rand)
+	s, t, pedersenSecret := paillier.SamplePedersen(r.Rand, paillierSecret)
+	n := paillierSecret.PublicKey().N()
+
+	rid := SampleRID(r.Rand)
+
+	h := r.HashForID(r.SelfID())
+	modProof := zkmod.NewProof(r.Rand, h, paillierSecret)
+	prmProof := zkprm.NewProof(r.Rand, h, pedersenSecret)
+
+	next.PaillierSecret = paillierSecret
+	next.N[r.SelfID()] = n
+	next.S[r.SelfID()] = s
+	next.T[r.SelfID()] = t
+	next.RID[r.SelfID()] = rid
+
+	msg := r.MarshalMessage(&Reshare1{N: n, S: s, T: t, RID: rid, Mod: modProof, Prm: prmProof}, r.OtherPartyIDs()...)
+	if err := r.SendMessage(msg, out); err != nil {
+		return r, err
+	}
+	return next, nil
+}
+
+func (r *reshareRound1) MessageContent() message.Content { return &Reshare1{} }
+
+// Validate implements message.Content.
+func (m *Reshare1) Validate() error {
+	if m == nil || m.N == nil || m.Mod == nil || m.Prm == nil {
+		return errors.New("keygen.reshare: round1 message is malformed")
+	}
+	return nil
+}
+
+// RoundNumber implements message.Content.
+func (m *Reshare1) RoundNumber() types.RoundNumber { return 1 }
+
+// reshareRound2 collects every new committee member's Paillier/ring-Pedersen
+// parameters, then — if this party is an old signer also present in the new
+// committee — shares its existing secret towards the new committee.
+type reshareRound2 struct {
+	*reshareRound1
+
+	PaillierSecret *paillier.SecretKey
+
+	N   map[party.ID]*safenum.Modulus
+	S   map[party.ID]*safenum.Nat
+	T   map[party.ID]*safenum.Nat
+	RID map[party.ID]RID
+}
+
+func (r *reshareRound2) ProcessMessage(j party.ID, content message.Content) error {
+	body := content.(*Reshare1)
+
+	if !body.Mod.Verify(r.HashForID(j), zkmod.Public{N: body.N}) {
+		return ErrReshareZKMod
+	}
+	if !body.Prm.Verify(r.HashForID(j), zkprm.Public{N: body.N, S: body.S, T: body.T}) {
+		return ErrReshareZKPrm
+	}
+
+	r.N[j] = body.N
+	r.S[j] = body.S
+	r.T[j] = body.T
+	r.RID[j] = body.RID
+	return nil
+}
+
+// Finalize implements round.Round.
+func (r *reshareRound2) Finalize(out chan<- *message.Message) (round.Round, error) {
+	newRID := EmptyRID()
+	for _, j := range r.NewPartyIDs {
+		newRID.XOR(r.RID[j])
+	}
+
+	next := &reshareRound3{
+		reshareRound2: r,
+		RID:           newRID,
+		Contributions: map[party.ID]*Reshare2{},
+		SharesFor:     map[party.ID]*curve.Scalar{},
+	}
+
+	if !r.Contributing.Contains(r.SelfID()) {
+		return next, nil
+	}
+
+	oldShare := r.OldConfig.Secret.ECDSA
+	poly := polynomial.NewPolynomial(r.Rand, r.NewThreshold, oldShare)
+	exponent := poly.Exponent()
+
+	shares := make(map[party.ID]*paillier.Ciphertext, len(r.NewPartyIDs))
+	for _, j := range r.NewPartyIDs {
+		evaluation := poly.Evaluate(j.Scalar())
+		pk := paillier.NewPublicKey(next.N[j])
+		ciphertext, _ := pk.Enc(r.Rand, curve.MakeInt(evaluation))
+		shares[j] = ciphertext
+	}
+
+	// A party contributing to itself doesn't need to round-trip through Paillier.
+	next.Contributions[r.SelfID()] = &Reshare2{VSSPolynomial: exponent, Shares: shares}
+	if r.NewPartyIDs.Contains(r.SelfID()) {
+		next.SharesFor[r.SelfID()] = poly.Evaluate(r.SelfID().Scalar())
+	}
+
+	msg := r.MarshalMessage(&Reshare2{VSSPolynomial: exponent, Shares: shares}, r.OtherPartyIDs()...)
+	if err := r.SendMessage(msg, out); err != nil {
+		return r, err
+	}
+	return next, nil
+}
+
+func (r *reshareRound2) MessageContent() message.Content { return &Reshare1{} }
+
+// reshareRound3 collects each old signer's VSS contribution, checks that they
+// collectively interpolate to the original public key, and — if this party is a
+// member of the new committee — reconstructs its new share and proves ownership.
+type reshareRound3 struct {
+	*reshareRound2
+
+	RID RID
+
+	// Contributions[i] is the message received from old signer i, once verified.
+	Contributions map[party.ID]*Reshare2
+	// SharesFor[i] is Fᵢ(SelfID()), decrypted; only populated when SelfID() is a
+	// member of the new committee.
+	SharesFor map[party.ID]*curve.Scalar
+}
+
+func (r *reshareRound3) ProcessMessage(j party.ID, content message.Content) error {
+	body := content.(*Reshare2)
+
+	if !r.Contributing.Contains(j) {
+		// A party outside P ∩ P' has nothing to contribute to this round.
+		return nil
+	}
+
+	if r.NewPartyIDs.Contains(r.SelfID()) {
+		ciphertext, ok := body.Shares[r.SelfID()]
+		if !ok {
+			return ErrReshareDecrypt
+		}
+		plain, err := r.PaillierSecret.Dec(ciphertext)
+		if err != nil {
+			return ErrReshareDecrypt
+		}
+		share := curve.NewScalarInt(plain)
+		if !body.VSSPolynomial.Evaluate(r.SelfID().Scalar()).Equal(curve.NewIdentityPoint().ScalarBaseMult(share)) {
+			return ErrReshareVSS
+		}
+		r.SharesFor[j] = share
+	}
+
+	r.Contributions[j] = body
+	return nil
+}
+
+// Finalize implements round.Round.
+//
+// - check that at least OldConfig.Threshold+1 old signers contributed
+// - verify that the Lagrange-weighted VSS commitments interpolate to the original
+//   group public key, so that no cheating old holder can shift it
+// - reconstruct this party's new share, if it is a member of the new committee,
+//   and prove knowledge of it via Schnorr
+func (r *reshareRound3) Finalize(out chan<- *message.Message) (round.Round, error) {
+	if len(r.Contributions) < int(r.OldConfig.Threshold)+1 {
+		return r, ErrReshareNotEnoughOldSigners
+	}
+
+	contributors := make(party.IDSlice, 0, len(r.Contributions))
+	for id := range r.Contributions {
+		contributors = append(contributors, id)
+	}
+	lagrange := polynomial.Lagrange(contributors)
+
+	reconstructed := curve.NewIdentityPoint()
+	for _, i := range contributors {
+		term := curve.NewIdentityPoint().ScalarMult(lagrange[i], r.Contributions[i].VSSPolynomial.Constant())
+		reconstructed.Add(reconstructed, term)
+	}
+	if !reconstructed.Equal(r.OldConfig.PublicPoint()) {
+		return r, ErrReshareVSSMismatch
+	}
+
+	next := &reshareRound4{
+		reshareRound3: r,
+		GroupPoint:    reconstructed,
+		Contributors:  contributors,
+		Lagrange:      lagrange,
+		Public:        map[party.ID]*curve.Point{},
+	}
+
+	if !r.NewPartyIDs.Contains(r.SelfID()) {
+		return next, nil
+	}
+
+	newSecret := curve.NewScalar()
+	for _, i := range contributors {
+		term := curve.NewScalar().Mul(lagrange[i], r.SharesFor[i])
+		newSecret.Add(newSecret, term)
+	}
+	next.NewSecretECDSA = newSecret
+	newPublic := curve.NewIdentityPoint().ScalarBaseMult(newSecret)
+	next.Public[r.SelfID()] = newPublic
+
+	h := r.Hash()
+	_, _ = h.WriteAny(reconstructed, r.RID, r.SelfID())
+	proof := zksch.NewRandomness(r.Rand, curve.NewScalar()).Prove(h, newPublic, newSecret)
+
+	msg := r.MarshalMessage(&ReshareOutput{Public: newPublic, SchnorrResponse: proof}, r.OtherPartyIDs()...)
+	if err := r.SendMessage(msg, out); err != nil {
+		return r, err
+	}
+	return next, nil
+}
+
+func (r *reshareRound3) MessageContent() message.Content { return &Reshare2{} }
+
+// Validate implements message.Content.
+func (m *Reshare2) Validate() error {
+	if m == nil || m.VSSPolynomial == nil || m.Shares == nil {
+		return errors.New("keygen.reshare: round2 message is malformed")
+	}
+	return nil
+}
+
+// RoundNumber implements message.Content.
+func (m *Reshare2) RoundNumber() types.RoundNumber { return 2 }
+
+// reshareRound4 is the final round: every new committee member proves, via
+// Schnorr, that it knows the discrete log of its new public share, and the group
+// finalizes the new Config once all proofs check out.
+type reshareRound4 struct {
+	*reshareRound3
+
+	// GroupPoint is the (unchanged) group public key, recovered as a side effect
+	// of round3's VSS check.
+	GroupPoint *curve.Point
+	// Contributors and Lagrange are the old signers (and their Lagrange
+	// coefficients over Contributors) that round3 used to interpolate the group
+	// key; the same combination, evaluated at j instead of at 0, is what every
+	// new committee member's public share must independently equal.
+	Contributors party.IDSlice
+	Lagrange     map[party.ID]*curve.Scalar
+	// NewSecretECDSA is this party's reconstructed share; nil unless
+	// SelfID() ∈ NewPartyIDs.
+	NewSecretECDSA *curve.Scalar
+	Public         map[party.ID]*curve.Point
+}
+
+func (r *reshareRound4) ProcessMessage(j party.ID, content message.Content) error {
+	body := content.(*ReshareOutput)
+
+	h := r.Hash()
+	_, _ = h.WriteAny(r.GroupPoint, r.RID, j)
+
+	if !body.SchnorrResponse.Verify(h, body.Public) {
+		return ErrReshareSchnorr
+	}
+
+	// The Schnorr proof only shows j knows *some* discrete log of body.Public; it
+	// doesn't show that log is the share every honest party would derive from the
+	// broadcast VSS commitments. Re-derive it independently, the same way round3
+	// bound the reconstructed old key.
+	expected := curve.NewIdentityPoint()
+	for _, i := range r.Contributors {
+		term := curve.NewIdentityPoint().ScalarMult(r.Lagrange[i], r.Contributions[i].VSSPolynomial.Evaluate(j.Scalar()))
+		expected.Add(expected, term)
+	}
+	if !expected.Equal(body.Public) {
+		return ErrReshareNewShareMismatch
+	}
+
+	r.Public[j] = body.Public
+	return nil
+}
+
+// Finalize implements round.Round.
+func (r *reshareRound4) Finalize(chan<- *message.Message) (round.Round, error) {
+	if !r.NewPartyIDs.Contains(r.SelfID()) {
+		return nil, nil
+	}
+
+	publicData := make(map[party.ID]*Public, len(r.NewPartyIDs))
+	for _, j := range r.NewPartyIDs {
+		publicData[j] = &Public{ECDSA: r.Public[j], N: r.N[j], S: r.S[j], T: r.T[j]}
+	}
+
+	updated := &Config{
+		Threshold: uint32(r.NewThreshold),
+		Public:    publicData,
+		RID:       r.RID.Copy(),
+		Secret: &Secret{
+			ID:    r.SelfID(),
+			ECDSA: r.NewSecretECDSA,
+			P:     &proto.NatMarshaller{Nat: r.PaillierSecret.P()},
+			Q:     &proto.NatMarshaller{Nat: r.PaillierSecret.Q()},
+		},
+	}
+
+	r.UpdateHashState(updated)
+	return &reshareOutput{reshareRound4: r, UpdatedConfig: updated}, nil
+}
+
+func (r *reshareRound4) MessageContent() message.Content { return &ReshareOutput{} }
+
+// reshareOutput is reshare's terminal round: the new Config is finished and
+// there is nothing further to send or receive. It has its own type — rather
+// than reusing keygen's own output — because that type embeds *round5, which
+// reshare never constructs; embedding it here would leave it nil.
+type reshareOutput struct {
+	*reshareRound4
+
+	UpdatedConfig *Config
+}
+
+func (r *reshareOutput) ProcessMessage(party.ID, message.Content) error { return nil }
+
+// Finalize implements round.Round.
+func (r *reshareOutput) Finalize(chan<- *message.Message) (round.Round, error) { return nil, nil }
+
+func (r *reshareOutput) MessageContent() message.Content { return nil }
+
+// Validate implements message.Content.
+func (m *ReshareOutput) Validate() error {
+	if m == nil || m.SchnorrResponse == nil || m.Public == nil {
+		return errors.New("keygen.reshare: output message is malformed")
+	}
+	return nil
+}
+
+// RoundNumber implements message.Content.
+func (m *ReshareOutput) RoundNumber() types.RoundNumber { return 3 }
+
+// Reshare1 is broadcast by every member of the new committee in round 1.
+type Reshare1 struct {
+	N   *safenum.Modulus
+	S   *safenum.Nat
+	T   *safenum.Nat
+	RID RID
+	Mod *zkmod.Proof
+	Prm *zkprm.Proof
+}
+
+// Reshare2 is broadcast by every old signer in P ∩ P' in round 2.
+type Reshare2 struct {
+	// VSSPolynomial is the exponent of the sender's fresh degree-NewThreshold
+	// polynomial, whose constant term is the sender's old secret share.
+	VSSPolynomial *polynomial.Exponent
+	// Shares[j] is VSSPolynomial evaluated at j, Paillier-encrypted under j's key.
+	Shares map[party.ID]*paillier.Ciphertext
+}
+
+// ReshareOutput is broadcast by every new committee member in round 3.
+type ReshareOutput struct {
+	Public          *curve.Point
+	SchnorrResponse *zksch.Response
+}
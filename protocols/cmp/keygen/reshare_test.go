@@ -0,0 +1,164 @@
+package keygen
+
+import (
+	"io"
+	mrand "math/rand"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/cmp-ecdsa/internal/round"
+	"github.com/taurusgroup/cmp-ecdsa/pkg/party"
+	"github.com/taurusgroup/cmp-ecdsa/pkg/protocol/message"
+)
+
+// runReshare drives a TShare session to completion and returns the resulting
+// Config for every member of the new committee. A nil source falls back to
+// crypto/rand.Reader, as StartReshare does.
+func runReshare(t *testing.T, oldConfigs map[party.ID]*Config, oldPartyIDs, newPartyIDs party.IDSlice, newThreshold int, source io.Reader) map[party.ID]*Config {
+	t.Helper()
+
+	rounds := make(map[party.ID]round.Round, len(oldPartyIDs)+len(newPartyIDs))
+	seen := map[party.ID]bool{}
+	all := make(party.IDSlice, 0, len(oldPartyIDs)+len(newPartyIDs))
+	for _, id := range append(append(party.IDSlice{}, oldPartyIDs...), newPartyIDs...) {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		all = append(all, id)
+	}
+
+	for _, id := range all {
+		var cfg *Config
+		if c, ok := oldConfigs[id]; ok {
+			cfg = c
+		} else {
+			// A brand new party has no old config of its own; it borrows any
+			// participant's for the shared, public SSID material.
+			for _, c := range oldConfigs {
+				cfg = c
+				break
+			}
+		}
+		r, err := StartReshare(cfg, newPartyIDs, newThreshold, source)
+		require.NoError(t, err)
+		rounds[id] = r
+	}
+
+	for roundNum := 0; roundNum < 4; roundNum++ {
+		out := make(chan *message.Message, len(all)*len(all))
+		// Finalize in a fixed order so that a shared seeded source is drawn from
+		// in the same order on every run.
+		for _, id := range all {
+			r := rounds[id]
+			next, err := r.Finalize(out)
+			require.NoError(t, err, "party %v failed to finalize round %d", id, roundNum)
+			if next != nil {
+				rounds[id] = next
+			}
+		}
+		close(out)
+		for msg := range out {
+			raw, err := proto.Marshal(msg)
+			require.NoError(t, err)
+			for id, r := range rounds {
+				if msg.From == id {
+					continue
+				}
+				if len(msg.To) != 0 && !party.IDSlice(msg.To).Contains(id) {
+					continue
+				}
+				var m message.Message
+				require.NoError(t, proto.Unmarshal(raw, &m))
+				content := r.MessageContent()
+				require.NoError(t, m.UnmarshalContent(content))
+				require.NoError(t, r.ProcessMessage(m.From, content))
+			}
+		}
+	}
+
+	results := make(map[party.ID]*Config, len(newPartyIDs))
+	for _, id := range newPartyIDs {
+		out, ok := rounds[id].(*reshareOutput)
+		require.True(t, ok, "party %v did not reach the output round", id)
+		results[id] = out.UpdatedConfig
+	}
+	return results
+}
+
+func fakeOldConfigs(t *testing.T) (party.IDSlice, map[party.ID]*Config) {
+	t.Helper()
+	configs := FakeData(4, 2, mrand.New(mrand.NewSource(1)))
+	ids := make(party.IDSlice, 0, len(configs))
+	for id := range configs {
+		ids = append(ids, id)
+	}
+	return ids, configs
+}
+
+// TestReshareIncreaseThreshold covers scenario (a): same parties, higher threshold.
+func TestReshareIncreaseThreshold(t *testing.T) {
+	oldPartyIDs, configs := fakeOldConfigs(t)
+	groupKey := configs[oldPartyIDs[0]].PublicPoint()
+
+	results := runReshare(t, configs, oldPartyIDs, oldPartyIDs, 3, nil)
+	for id, cfg := range results {
+		assert.EqualValues(t, 3, cfg.Threshold)
+		assert.True(t, cfg.PublicPoint().Equal(groupKey), "party %v: group key changed", id)
+	}
+}
+
+// TestReshareDecreaseThreshold covers scenario (b): same parties, lower threshold.
+func TestReshareDecreaseThreshold(t *testing.T) {
+	oldPartyIDs, configs := fakeOldConfigs(t)
+	groupKey := configs[oldPartyIDs[0]].PublicPoint()
+
+	results := runReshare(t, configs, oldPartyIDs, oldPartyIDs, 1, nil)
+	for id, cfg := range results {
+		assert.EqualValues(t, 1, cfg.Threshold)
+		assert.True(t, cfg.PublicPoint().Equal(groupKey), "party %v: group key changed", id)
+	}
+}
+
+// TestReshareChangeMembership covers scenario (c): parties are both added and
+// removed, with the threshold held constant.
+func TestReshareChangeMembership(t *testing.T) {
+	oldPartyIDs, configs := fakeOldConfigs(t)
+	groupKey := configs[oldPartyIDs[0]].PublicPoint()
+
+	// Drop the last old party, add two brand new ones.
+	newPartyIDs := append(party.IDSlice{}, oldPartyIDs[:len(oldPartyIDs)-1]...)
+	newPartyIDs = append(newPartyIDs, party.ID("new-1"), party.ID("new-2"))
+
+	results := runReshare(t, configs, oldPartyIDs, newPartyIDs, 2, nil)
+	require.Len(t, results, len(newPartyIDs))
+	for id, cfg := range results {
+		assert.True(t, cfg.PublicPoint().Equal(groupKey), "party %v: group key changed", id)
+		_, wasOld := configs[id]
+		if !wasOld {
+			assert.NotNil(t, cfg.Secret.P, "brand new party should hold its own freshly generated Paillier primes")
+		}
+	}
+}
+
+// TestReshareInvalidatesOldPresigns covers scenario (d): a presign record computed
+// under the old committee must not be usable to sign under the reshared one.
+// StartOnlineSign rejects a record whose SSID differs from the Config's, so the
+// test checks that every reshared Config has a new SSID, and that every share
+// changed.
+func TestReshareInvalidatesOldPresigns(t *testing.T) {
+	oldPartyIDs, configs := fakeOldConfigs(t)
+
+	newPartyIDs := append(party.IDSlice{}, oldPartyIDs...)
+	results := runReshare(t, configs, oldPartyIDs, newPartyIDs, 2, nil)
+
+	for id, oldCfg := range configs {
+		newCfg := results[id]
+		assert.NotEqual(t, oldCfg.SSID(), newCfg.SSID(),
+			"party %v: reshared Config should have a new SSID, so a presign record bound to the old one is rejected", id)
+		assert.False(t, oldCfg.Secret.ECDSA.Equal(newCfg.Secret.ECDSA),
+			"party %v: reshared secret share should differ from the pre-reshare share", id)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/taurusgroup/cmp-ecdsa/internal/round"
 	"github.com/taurusgroup/cmp-ecdsa/pkg/math/curve"
 	"github.com/taurusgroup/cmp-ecdsa/pkg/math/polynomial"
+	"github.com/taurusgroup/cmp-ecdsa/pkg/math/sample"
 	"github.com/taurusgroup/cmp-ecdsa/pkg/party"
 	"github.com/taurusgroup/cmp-ecdsa/pkg/protocol/message"
 	"github.com/taurusgroup/cmp-ecdsa/pkg/protocol/types"
@@ -22,15 +23,25 @@ type round5 struct {
 	// RID = ⊕ⱼ RIDⱼ
 	// Random ID generated by taking the XOR of all ridᵢ
 	RID RID
+
+	// DisableBatchVerify makes Finalize skip the batched check and verify each
+	// sender's contribution on its own (verifySequential). Off by default; a
+	// failed batch also falls back to verifySequential, so either way the error
+	// names the first failing check.
+	DisableBatchVerify bool
+
+	// pending buffers every Keygen5 received so far; verified in bulk in Finalize.
+	pending map[party.ID]*Keygen5
 }
 
 // ProcessMessage implements round.Round.
 //
-// - decrypt share
-// - verify VSS.
+// Verification is deferred to Finalize so that the VSS check and the zkmod/zkprm
+// proofs can be batched across all senders instead of checked one at a time; only
+// the decryption itself, which is cheap and unique to this party, happens here.
 func (r *round5) ProcessMessage(j party.ID, content message.Content) error {
 	body := content.(*Keygen5)
-	// decrypt share
+
 	DecryptedShare, err := r.PaillierSecret.Dec(body.Share)
 	if err != nil {
 		return err
@@ -40,25 +51,94 @@ func (r *round5) ProcessMessage(j party.ID, content message.Content) error {
 		return ErrRound5Decrypt
 	}
 
-	// verify share with VSS
-	ExpectedPublicShare := r.VSSPolynomials[j].Evaluate(r.SelfID().Scalar()) // Fⱼ(i)
-	PublicShare := curve.NewIdentityPoint().ScalarBaseMult(Share)
-	// X == Fⱼ(i)
-	if !PublicShare.Equal(ExpectedPublicShare) {
-		return ErrRound5VSS
+	if r.pending == nil {
+		r.pending = make(map[party.ID]*Keygen5, len(r.PartyIDs()))
 	}
+	r.pending[j] = body
+	r.ShareReceived[j] = Share
+	return nil
+}
 
-	// verify zkmod
-	if !body.Mod.Verify(r.HashForID(j), zkmod.Public{N: r.N[j]}) {
-		return ErrRound5ZKMod
+// verifyContributions checks every buffered Keygen5 message's VSS share and
+// zkmod/zkprm proofs.
+//
+// Scope note: only the VSS check is actually batched. With batching enabled
+// (the default), it's folded into a single multi-scalar multiplication:
+// sampling a random ρⱼ per sender j and accepting iff
+// Σⱼ ρⱼ·(g^{shareⱼ} − Fⱼ(i)) = 0, which is overwhelmingly likely to fail if any
+// one term doesn't. zkmod and zkprm — the more expensive proofs, and the
+// dominant cost for large committees — are still verified one full proof at a
+// time per sender below; zkmod.Proof/zkprm.Proof don't expose the raw
+// verification equation (the per-challenge-bit exponentiations), only a single
+// Verify() that does both the exponentiation and the equality check, so there's
+// no per-sender multi-exponentiation to fold them into without first reworking
+// those packages. That's a real gap against what the request asked for — it
+// batches the cheap EC-scalar-mult part of round5's cost, not the dominant
+// modular-exponentiation part — and is called out here rather than left
+// implicit. On any VSS batch failure we fall back to verifying every sender
+// individually, so the round can return a specific ErrRound5* tied to the
+// actual culprit rather than just "the batch failed".
+func (r *round5) verifyContributions() error {
+	senders := make(party.IDSlice, 0, len(r.pending))
+	for j := range r.pending {
+		senders = append(senders, j)
 	}
 
-	// verify zkprm
-	if !body.Prm.Verify(r.HashForID(j), zkprm.Public{N: r.N[j], S: r.S[j], T: r.T[j]}) {
-		return ErrRound5ZKPrm
+	if !r.DisableBatchVerify {
+		if err := r.verifyBatch(senders); err == nil {
+			return nil
+		}
 	}
+	return r.verifySequential(senders)
+}
 
-	r.ShareReceived[j] = Share
+func (r *round5) verifyBatch(senders party.IDSlice) error {
+	weightedShares := curve.NewScalar()
+	weightedCommitments := curve.NewIdentityPoint()
+	for _, j := range senders {
+		rho := sample.Scalar(r.Rand)
+
+		weightedShares.Add(weightedShares, curve.NewScalar().Mul(rho, r.ShareReceived[j]))
+
+		Fj := r.VSSPolynomials[j].Evaluate(r.SelfID().Scalar())
+		weightedCommitments.Add(weightedCommitments, curve.NewIdentityPoint().ScalarMult(rho, Fj))
+	}
+
+	if !curve.NewIdentityPoint().ScalarBaseMult(weightedShares).Equal(weightedCommitments) {
+		return ErrRound5VSS
+	}
+
+	for _, j := range senders {
+		body := r.pending[j]
+		if !body.Mod.Verify(r.HashForID(j), zkmod.Public{N: r.N[j]}) {
+			return ErrRound5ZKMod
+		}
+		if !body.Prm.Verify(r.HashForID(j), zkprm.Public{N: r.N[j], S: r.S[j], T: r.T[j]}) {
+			return ErrRound5ZKPrm
+		}
+	}
+	return nil
+}
+
+// verifySequential re-checks every sender's contribution one at a time, so that a
+// failed batch can be attributed to a specific party.
+func (r *round5) verifySequential(senders party.IDSlice) error {
+	for _, j := range senders {
+		body := r.pending[j]
+
+		ExpectedPublicShare := r.VSSPolynomials[j].Evaluate(r.SelfID().Scalar())
+		PublicShare := curve.NewIdentityPoint().ScalarBaseMult(r.ShareReceived[j])
+		if !PublicShare.Equal(ExpectedPublicShare) {
+			return ErrRound5VSS
+		}
+
+		if !body.Mod.Verify(r.HashForID(j), zkmod.Public{N: r.N[j]}) {
+			return ErrRound5ZKMod
+		}
+		if !body.Prm.Verify(r.HashForID(j), zkprm.Public{N: r.N[j], S: r.S[j], T: r.T[j]}) {
+			return ErrRound5ZKPrm
+		}
+	}
 	return nil
 }
 
@@ -71,6 +151,10 @@ func (r *round5) ProcessMessage(j party.ID, content message.Content) error {
 // - write new ssid hash to old hash state
 // - create proof of knowledge of secret.
 func (r *round5) Finalize(out chan<- *message.Message) (round.Round, error) {
+	if err := r.verifyContributions(); err != nil {
+		return r, err
+	}
+
 	// add all shares to our secret
 	UpdatedSecretECDSA := curve.NewScalar().Set(r.PreviousSecretECDSA)
 	for _, j := range r.PartyIDs() {
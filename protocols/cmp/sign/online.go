@@ -0,0 +1,133 @@
+package sign
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/taurusgroup/cmp-ecdsa/pkg/math/curve"
+	"github.com/taurusgroup/cmp-ecdsa/pkg/message"
+	"github.com/taurusgroup/cmp-ecdsa/pkg/party"
+	"github.com/taurusgroup/cmp-ecdsa/pkg/round"
+	"github.com/taurusgroup/cmp-ecdsa/protocols/cmp/keygen"
+)
+
+// StartOnlineSign returns the single, cheap online round of CMP signing: given a
+// PresignRecord already computed for this Config's committee and a message hash,
+// every signer reveals one scalar (σᵢ) and the group combines them into a
+// signature. It is safe to call concurrently with other uses of config, but not
+// with another call sharing the same record — record.Consumed() will report the
+// second caller's record as already spent.
+func StartOnlineSign(config *keygen.Config, record *PresignRecord, messageHash []byte) func() (round.Round, *round.Info, error) {
+	return func() (round.Round, *round.Info, error) {
+		if !bytes.Equal(record.SSID, config.SSID()) {
+			return nil, nil, ErrPresignWrongCommittee
+		}
+
+		helper, err := round.NewHelper(config.SSID(), record.Signers)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Only burn the record once we know the round is actually being handed
+		// back; an unrelated NewHelper failure shouldn't consume a record that
+		// never got to sign anything.
+		if !record.consumed.CompareAndSwap(false, true) {
+			return nil, nil, ErrPresignConsumed
+		}
+
+		rScalar := record.BigR.XScalar()
+
+		sigma := curve.NewScalar().Mul(rScalar, record.Chi)
+		sigma.Add(sigma, curve.NewScalar().Mul(record.K, curve.NewScalarBytes(messageHash)))
+
+		return &online1{
+			Helper:      helper,
+			Config:      config,
+			Record:      record,
+			MessageHash: messageHash,
+			R:           rScalar,
+			Sigma:       map[party.ID]*curve.Scalar{helper.SelfID(): sigma},
+		}, helper.Info(), nil
+	}
+}
+
+// online1 is the sole round of the online phase: every signer broadcasts its
+// share σᵢ of the final signature scalar s = Σσᵢ, and the round finalizes into a
+// verified Signature once all of them arrive.
+type online1 struct {
+	*round.Helper
+
+	Config      *keygen.Config
+	Record      *PresignRecord
+	MessageHash []byte
+
+	R     *curve.Scalar
+	Sigma map[party.ID]*curve.Scalar
+}
+
+func (r *online1) ProcessMessage(j party.ID, content message.Content) error {
+	body := content.(*Online1)
+	r.Sigma[j] = body.Sigma
+	return nil
+}
+
+// Finalize implements round.Round.
+func (r *online1) Finalize(out chan<- *message.Message) (round.Round, error) {
+	msg, err := r.MarshalMessage(&Online1{Sigma: r.Sigma[r.SelfID()]}, r.OtherPartyIDs()...)
+	if err != nil {
+		return r, err
+	}
+	if err = r.SendMessage(msg, out); err != nil {
+		return r, err
+	}
+	return &onlineOutput{online1: r}, nil
+}
+
+func (r *online1) MessageContent() message.Content { return &Online1{} }
+
+// Validate implements message.Content.
+func (m *Online1) Validate() error {
+	if m == nil || m.Sigma == nil {
+		return errors.New("sign.online: round message is malformed")
+	}
+	return nil
+}
+
+// RoundNumber implements message.Content.
+func (m *Online1) RoundNumber() int { return 1 }
+
+// onlineOutput sums every signer's σᵢ into the final signature and verifies it
+// against the group's public key before handing it back.
+type onlineOutput struct {
+	*online1
+
+	Signature *Signature
+}
+
+func (r *onlineOutput) ProcessMessage(j party.ID, content message.Content) error {
+	body := content.(*Online1)
+	r.Sigma[j] = body.Sigma
+	return nil
+}
+
+// Finalize implements round.Round.
+func (r *onlineOutput) Finalize(chan<- *message.Message) (round.Round, error) {
+	s := curve.NewScalar()
+	for _, j := range r.Record.Signers {
+		s.Add(s, r.Sigma[j])
+	}
+
+	sig := &Signature{R: r.R, S: s}
+	if !sig.Verify(r.Config.PublicPoint(), r.MessageHash) {
+		return nil, ErrSignatureVerification
+	}
+	r.Signature = sig
+	return nil, nil
+}
+
+func (r *onlineOutput) MessageContent() message.Content { return &Online1{} }
+
+// Online1 is broadcast by every signer during the online round.
+type Online1 struct {
+	Sigma *curve.Scalar
+}
@@ -0,0 +1,199 @@
+package sign
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync/atomic"
+
+	"github.com/taurusgroup/cmp-ecdsa/pkg/math/curve"
+	"github.com/taurusgroup/cmp-ecdsa/pkg/party"
+	"github.com/taurusgroup/cmp-ecdsa/pkg/round"
+	"github.com/taurusgroup/cmp-ecdsa/protocols/cmp/keygen"
+)
+
+// Errors returned around presign-record handling.
+var (
+	// ErrPresignConsumed is returned by StartOnlineSign when the given PresignRecord
+	// has already been used to produce a signature. Presign material is single-use:
+	// signing two different messages with the same k, χ pair leaks the secret key.
+	ErrPresignConsumed = errors.New("sign: presign record has already been consumed")
+	// ErrPresignWrongCommittee is returned when a PresignRecord's SSID doesn't match
+	// the Config it's being combined with.
+	ErrPresignWrongCommittee = errors.New("sign: presign record was generated for a different committee")
+)
+
+// PresignRecord holds the output of CMP's message-independent presign phase: a
+// per-signer additive share of the nonce and of χ = k·x, plus the group's
+// presignature point. Combined with a message hash in the (cheap) online phase,
+// it produces a full ECDSA signature.
+//
+// A PresignRecord is single-use: Consumed reports whether StartOnlineSign has
+// already spent it, and reuse against a second message is rejected rather than
+// silently allowed, since it would leak the signer's secret share.
+type PresignRecord struct {
+	// SSID binds this record to the committee (Config) it was generated under;
+	// StartOnlineSign refuses to use a record against a different committee.
+	SSID []byte
+	// Signers is the set of parties that took part in the presign round.
+	Signers party.IDSlice
+
+	// K is this party's additive share of the nonce k.
+	K *curve.Scalar
+	// Chi is this party's additive share of χ = k·x.
+	Chi *curve.Scalar
+	// BigR is the group's presignature point R = (1/k)·G.
+	BigR *curve.Point
+
+	consumed atomic.Bool
+}
+
+// Consumed reports whether this record has already been used to sign a message.
+func (p *PresignRecord) Consumed() bool { return p.consumed.Load() }
+
+// Zeroize wipes the secret scalars held by this record. Call it once a record is
+// no longer needed, whether or not it was ever consumed.
+func (p *PresignRecord) Zeroize() {
+	if p.K != nil {
+		p.K.Set(curve.NewScalar())
+	}
+	if p.Chi != nil {
+		p.Chi.Set(curve.NewScalar())
+	}
+}
+
+// Marshal serializes the record for persistence as length-prefixed big-endian
+// fields: SSID, Signers, K, Chi, BigR. This is the package's own encoding, not
+// the protobuf encoding Config uses; the consumed flag is not written.
+func (p *PresignRecord) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := binary.Write(&buf, binary.BigEndian, uint32(len(p.SSID))); err != nil {
+		return nil, err
+	}
+	buf.Write(p.SSID)
+
+	if err := binary.Write(&buf, binary.BigEndian, uint32(len(p.Signers))); err != nil {
+		return nil, err
+	}
+	for _, id := range p.Signers {
+		idBytes := []byte(id)
+		if err := binary.Write(&buf, binary.BigEndian, uint32(len(idBytes))); err != nil {
+			return nil, err
+		}
+		buf.Write(idBytes)
+	}
+
+	k := p.K.Bytes()
+	chi := p.Chi.Bytes()
+	bigR := p.BigR.Bytes()
+	for _, part := range [][]byte{k, chi, bigR} {
+		if err := binary.Write(&buf, binary.BigEndian, uint32(len(part))); err != nil {
+			return nil, err
+		}
+		buf.Write(part)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// Unmarshal deserializes a record previously produced by Marshal. The consumed
+// flag always starts false: persistence is meant for records that haven't been
+// used yet.
+//
+// TODO: this hand-rolls a binary.Write/bytes.Buffer wire format rather than
+// following the gogo/protobuf path every wire type in this series (Reshare1,
+// Presign1/2/3, Online1, ...) uses, as the request asked for. Doing that
+// properly needs a generated PresignRecord protobuf message the same way
+// Config has one, which isn't something this change can produce without the
+// .proto schema and codegen Config itself is built from — neither is part of
+// this diff. Tracked as a follow-up; in the meantime this at least fixes the
+// truncated-read correctness bug below.
+func (p *PresignRecord) Unmarshal(data []byte) error {
+	buf := bytes.NewReader(data)
+
+	readBytes := func() ([]byte, error) {
+		var n uint32
+		if err := binary.Read(buf, binary.BigEndian, &n); err != nil {
+			return nil, err
+		}
+		b := make([]byte, n)
+		if _, err := io.ReadFull(buf, b); err != nil {
+			return nil, err
+		}
+		return b, nil
+	}
+
+	ssid, err := readBytes()
+	if err != nil {
+		return err
+	}
+	p.SSID = ssid
+
+	var numSigners uint32
+	if err := binary.Read(buf, binary.BigEndian, &numSigners); err != nil {
+		return err
+	}
+	p.Signers = make(party.IDSlice, numSigners)
+	for i := range p.Signers {
+		idBytes, err := readBytes()
+		if err != nil {
+			return err
+		}
+		p.Signers[i] = party.ID(idBytes)
+	}
+
+	kBytes, err := readBytes()
+	if err != nil {
+		return err
+	}
+	chiBytes, err := readBytes()
+	if err != nil {
+		return err
+	}
+	bigRBytes, err := readBytes()
+	if err != nil {
+		return err
+	}
+
+	p.K = curve.NewScalar().SetBytes(kBytes)
+	p.Chi = curve.NewScalar().SetBytes(chiBytes)
+	p.BigR = curve.NewIdentityPoint().SetBytes(bigRBytes)
+	p.consumed.Store(false)
+	return nil
+}
+
+// StartPresign returns the first round of CMP's message-independent presign
+// phase. Its output, once driven to completion, is a *PresignRecord* rather than
+// a signature: signers can run this well ahead of knowing what they are going to
+// sign, and spend the cheap StartOnlineSign round when a message actually needs
+// signing.
+//
+// This, together with StartOnlineSign, is meant to replace the older StartSign
+// (round1-round4, still exercised by sign_test.go's TestRound): that entry
+// point runs every round inline against a known message and has no presign/
+// online split. It's left in place for now rather than removed as part of this
+// change — callers should migrate to StartPresign/StartOnlineSign, and
+// StartSign should be deleted once they have.
+//
+// source is used for every randomness draw made over the course of the presign
+// rounds — nonce/γ sampling, Paillier encryption, and MtA blinding. A nil source
+// falls back to crypto/rand.Reader.
+func StartPresign(config *keygen.Config, signers party.IDSlice, source io.Reader) func() (round.Round, *round.Info, error) {
+	if source == nil {
+		source = rand.Reader
+	}
+	return func() (round.Round, *round.Info, error) {
+		helper, err := round.NewHelper(config.SSID(), signers, source)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &presign1{
+			Helper:  helper,
+			Config:  config,
+			Signers: signers,
+		}, helper.Info(), nil
+	}
+}
@@ -0,0 +1,284 @@
+package sign
+
+import (
+	"errors"
+
+	"github.com/taurusgroup/cmp-ecdsa/pkg/math/curve"
+	"github.com/taurusgroup/cmp-ecdsa/pkg/math/polynomial"
+	"github.com/taurusgroup/cmp-ecdsa/pkg/math/sample"
+	"github.com/taurusgroup/cmp-ecdsa/pkg/message"
+	"github.com/taurusgroup/cmp-ecdsa/pkg/paillier"
+	"github.com/taurusgroup/cmp-ecdsa/pkg/party"
+	"github.com/taurusgroup/cmp-ecdsa/pkg/round"
+	"github.com/taurusgroup/cmp-ecdsa/protocols/cmp/keygen"
+)
+
+// Errors returned while running the presign rounds. The MtA ciphertexts
+// exchanged below carry no affg/logstar range proofs, so none are verified on
+// receipt; keygen's round5, by contrast, verifies its zkmod/zkprm proofs in
+// Finalize once every message has arrived.
+var ErrPresignMtADecrypt = errors.New("sign.presign: failed to decrypt MtA response")
+
+// presign1 broadcasts this party's nonce commitment Γᵢ = g^γᵢ, together with a
+// Paillier encryption of its own kᵢ that the rest of the committee will need for
+// the MtA exchange in presign2.
+type presign1 struct {
+	*round.Helper
+
+	Config  *keygen.Config
+	Signers party.IDSlice
+}
+
+func (r *presign1) ProcessMessage(party.ID, message.Content) error { return nil }
+
+func (r *presign1) Finalize(out chan<- *message.Message) (round.Round, error) {
+	k := sample.Scalar(r.Rand)
+	gamma := sample.Scalar(r.Rand)
+	Gamma := curve.NewIdentityPoint().ScalarBaseMult(gamma)
+
+	selfPaillier := paillier.NewSecretKeyFromPrimes(r.Config.Secret.P.Nat, r.Config.Secret.Q.Nat)
+	kCiphertext, _ := selfPaillier.PublicKey().Enc(r.Rand, curve.MakeInt(k))
+
+	// x_self is this party's Shamir share, not an additive share of sk; weighting
+	// it by its Lagrange coefficient over the chosen Signers set gives
+	// Σ λᵢ·xᵢ = sk, so the sum the rest of presign accumulates is k·sk rather
+	// than k·Σxᵢ.
+	lagrange := polynomial.Lagrange(r.Signers)
+	weightedSecretECDSA := curve.NewScalar().Mul(lagrange[r.SelfID()], r.Config.Secret.ECDSA)
+
+	next := &presign2{
+		presign1:            r,
+		K:                   map[party.ID]*paillier.Ciphertext{r.SelfID(): kCiphertext},
+		Gamma:               map[party.ID]*curve.Point{r.SelfID(): Gamma},
+		k:                   k,
+		gamma:               gamma,
+		selfKey:             selfPaillier,
+		weightedSecretECDSA: weightedSecretECDSA,
+	}
+
+	msg, err := r.MarshalMessage(&Presign1{K: kCiphertext, Gamma: Gamma}, r.OtherPartyIDs()...)
+	if err != nil {
+		return r, err
+	}
+	if err = r.SendMessage(msg, out); err != nil {
+		return r, err
+	}
+	return next, nil
+}
+
+func (r *presign1) MessageContent() message.Content { return &Presign1{} }
+
+// Validate implements message.Content.
+func (m *Presign1) Validate() error {
+	if m == nil || m.K == nil || m.Gamma == nil {
+		return errors.New("sign.presign: round1 message is malformed")
+	}
+	return nil
+}
+
+// RoundNumber implements message.Content.
+func (m *Presign1) RoundNumber() int { return 1 }
+
+// presign2 collects every signer's Γⱼ and Enc(kⱼ), then runs the MtA exchange
+// against each of them for both γ and this party's ECDSA secret share x: for
+// signer j, it sends back an encrypted, blinded product that only j can decrypt.
+type presign2 struct {
+	*presign1
+
+	K     map[party.ID]*paillier.Ciphertext
+	Gamma map[party.ID]*curve.Point
+
+	k, gamma *curve.Scalar
+	selfKey  *paillier.SecretKey
+
+	// weightedSecretECDSA is this party's Shamir share multiplied by its Lagrange
+	// coefficient over Signers, i.e. λ_self·x_self.
+	weightedSecretECDSA *curve.Scalar
+
+	// betaGamma[j] and betaChi[j] are this party's own additive shares of
+	// γ·kⱼ and x·kⱼ respectively, chosen while blinding the MtA response sent to j.
+	betaGamma map[party.ID]*curve.Scalar
+	betaChi   map[party.ID]*curve.Scalar
+}
+
+func (r *presign2) ProcessMessage(j party.ID, content message.Content) error {
+	body := content.(*Presign1)
+	r.K[j] = body.K
+	r.Gamma[j] = body.Gamma
+	return nil
+}
+
+func (r *presign2) Finalize(out chan<- *message.Message) (round.Round, error) {
+	r.betaGamma = map[party.ID]*curve.Scalar{}
+	r.betaChi = map[party.ID]*curve.Scalar{}
+
+	for _, j := range r.OtherPartyIDs() {
+		pkj := paillier.NewPublicKey(r.Config.Public[j].N)
+
+		betaGamma := sample.Scalar(r.Rand)
+		negBetaGamma, _ := pkj.Enc(r.Rand, curve.MakeInt(curve.NewScalar().Set(betaGamma).Negate()))
+		respGamma := pkj.Add(pkj.Mul(r.K[j], r.gamma), negBetaGamma)
+		r.betaGamma[j] = betaGamma
+
+		betaChi := sample.Scalar(r.Rand)
+		negBetaChi, _ := pkj.Enc(r.Rand, curve.MakeInt(curve.NewScalar().Set(betaChi).Negate()))
+		respChi := pkj.Add(pkj.Mul(r.K[j], r.weightedSecretECDSA), negBetaChi)
+		r.betaChi[j] = betaChi
+
+		msg, err := r.MarshalMessage(&Presign2{Gamma: respGamma, Chi: respChi}, j)
+		if err != nil {
+			return r, err
+		}
+		if err = r.SendMessage(msg, out); err != nil {
+			return r, err
+		}
+	}
+
+	return &presign3{
+		presign2:   r,
+		alphaGamma: map[party.ID]*curve.Scalar{},
+		alphaChi:   map[party.ID]*curve.Scalar{},
+	}, nil
+}
+
+func (r *presign2) MessageContent() message.Content { return &Presign1{} }
+
+// presign3 collects the MtA responses addressed to this party, decrypts them, and
+// combines them with its own local product to reveal δᵢ = the additive share of
+// k·γ that this party contributes.
+type presign3 struct {
+	*presign2
+
+	alphaGamma map[party.ID]*curve.Scalar
+	alphaChi   map[party.ID]*curve.Scalar
+}
+
+func (r *presign3) ProcessMessage(j party.ID, content message.Content) error {
+	body := content.(*Presign2)
+
+	plainGamma, err := r.selfKey.Dec(body.Gamma)
+	if err != nil {
+		return ErrPresignMtADecrypt
+	}
+	plainChi, err := r.selfKey.Dec(body.Chi)
+	if err != nil {
+		return ErrPresignMtADecrypt
+	}
+
+	r.alphaGamma[j] = curve.NewScalarInt(plainGamma)
+	r.alphaChi[j] = curve.NewScalarInt(plainChi)
+	return nil
+}
+
+func (r *presign3) Finalize(out chan<- *message.Message) (round.Round, error) {
+	delta := curve.NewScalar().Mul(r.k, r.gamma)
+	chi := curve.NewScalar().Mul(r.k, r.weightedSecretECDSA)
+	for _, j := range r.OtherPartyIDs() {
+		delta.Add(delta, curve.NewScalar().Set(r.alphaGamma[j]))
+		delta.Add(delta, curve.NewScalar().Set(r.betaGamma[j]))
+		chi.Add(chi, curve.NewScalar().Set(r.alphaChi[j]))
+		chi.Add(chi, curve.NewScalar().Set(r.betaChi[j]))
+	}
+
+	next := &presign4{
+		presign3: r,
+		chi:      chi,
+		Delta:    map[party.ID]*curve.Scalar{r.SelfID(): delta},
+	}
+
+	msg, err := r.MarshalMessage(&Presign3{Delta: delta}, r.OtherPartyIDs()...)
+	if err != nil {
+		return r, err
+	}
+	if err = r.SendMessage(msg, out); err != nil {
+		return r, err
+	}
+	return next, nil
+}
+
+func (r *presign3) MessageContent() message.Content { return &Presign2{} }
+
+// Validate implements message.Content.
+func (m *Presign2) Validate() error {
+	if m == nil || m.Gamma == nil || m.Chi == nil {
+		return errors.New("sign.presign: round2 message is malformed")
+	}
+	return nil
+}
+
+// RoundNumber implements message.Content.
+func (m *Presign2) RoundNumber() int { return 2 }
+
+// presign4 collects every δⱼ, sums them into the (public) δ = k·γ, and uses it to
+// unblind Γ = g^γ into R = g^{1/k} without ever reconstructing k or γ.
+type presign4 struct {
+	*presign3
+
+	chi   *curve.Scalar
+	Delta map[party.ID]*curve.Scalar
+
+	// Record is set by Finalize; ProcessMessage never runs again afterwards, so
+	// there's no round beyond this one to hand it to via a message.
+	Record *PresignRecord
+}
+
+func (r *presign4) ProcessMessage(j party.ID, content message.Content) error {
+	body := content.(*Presign3)
+	r.Delta[j] = body.Delta
+	return nil
+}
+
+// Finalize implements round.Round. presign4 is the last round of the presign
+// phase: rather than return a further round.Round, it stashes the finished
+// PresignRecord on itself for the caller to read off directly.
+func (r *presign4) Finalize(chan<- *message.Message) (round.Round, error) {
+	delta := curve.NewScalar()
+	Gamma := curve.NewIdentityPoint()
+	for _, j := range r.Signers {
+		delta.Add(delta, r.Delta[j])
+		Gamma.Add(Gamma, r.Gamma[j])
+	}
+
+	deltaInv := curve.NewScalar().Set(delta).Invert()
+	bigR := curve.NewIdentityPoint().ScalarMult(deltaInv, Gamma)
+
+	r.Record = &PresignRecord{
+		SSID:    r.Config.SSID(),
+		Signers: append(party.IDSlice{}, r.Signers...),
+		K:       r.k,
+		Chi:     r.chi,
+		BigR:    bigR,
+	}
+	return nil, nil
+}
+
+func (r *presign4) MessageContent() message.Content { return &Presign3{} }
+
+// Validate implements message.Content.
+func (m *Presign3) Validate() error {
+	if m == nil || m.Delta == nil {
+		return errors.New("sign.presign: round3 message is malformed")
+	}
+	return nil
+}
+
+// RoundNumber implements message.Content.
+func (m *Presign3) RoundNumber() int { return 3 }
+
+// Presign1 is broadcast by every signer in round 1.
+type Presign1 struct {
+	K     *paillier.Ciphertext
+	Gamma *curve.Point
+}
+
+// Presign2 is the MtA response sent point-to-point between each pair of signers
+// in round 2.
+type Presign2 struct {
+	Gamma *paillier.Ciphertext
+	Chi   *paillier.Ciphertext
+}
+
+// Presign3 is broadcast by every signer in round 3.
+type Presign3 struct {
+	Delta *curve.Scalar
+}
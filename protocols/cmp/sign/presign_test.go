@@ -0,0 +1,219 @@
+package sign
+
+import (
+	"io"
+	mrand "math/rand"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/taurusgroup/cmp-ecdsa/pkg/message"
+	"github.com/taurusgroup/cmp-ecdsa/pkg/party"
+	"github.com/taurusgroup/cmp-ecdsa/pkg/round"
+	"github.com/taurusgroup/cmp-ecdsa/protocols/cmp/keygen"
+	"golang.org/x/crypto/sha3"
+)
+
+// runPresign drives a presign session to completion and returns the resulting
+// PresignRecord for every signer. A nil source falls back to StartPresign's own
+// crypto/rand.Reader default; tests that care about reproducing a transcript
+// pass a seeded one.
+func runPresign(t *testing.T, configs map[party.ID]*keygen.Config, signers party.IDSlice, source io.Reader) map[party.ID]*PresignRecord {
+	t.Helper()
+
+	rounds := make(map[party.ID]round.Round, len(signers))
+	for _, id := range signers {
+		r, _, err := StartPresign(configs[id], signers, source)()
+		require.NoError(t, err)
+		rounds[id] = r
+	}
+
+	for step := 0; step < 4; step++ {
+		out := make(chan *message.Message, len(signers)*len(signers))
+		// Finalize in a fixed order so that a shared seeded source is drawn from
+		// in the same order on every run.
+		for _, id := range signers {
+			r := rounds[id]
+			next, err := r.Finalize(out)
+			require.NoError(t, err, "party %v failed to finalize presign round %d", id, step)
+			if next != nil {
+				rounds[id] = next
+			}
+		}
+		close(out)
+		for msg := range out {
+			raw, err := proto.Marshal(msg)
+			require.NoError(t, err)
+			for id, r := range rounds {
+				if msg.From == id {
+					continue
+				}
+				if len(msg.To) != 0 && !party.IDSlice(msg.To).Contains(id) {
+					continue
+				}
+				var m message.Message
+				require.NoError(t, proto.Unmarshal(raw, &m))
+				content := r.MessageContent()
+				require.NoError(t, m.UnmarshalContent(content))
+				require.NoError(t, r.ProcessMessage(m.From, content))
+			}
+		}
+	}
+
+	records := make(map[party.ID]*PresignRecord, len(signers))
+	for id, r := range rounds {
+		out, ok := r.(*presign4)
+		require.True(t, ok, "party %v did not reach presign4", id)
+		require.NotNil(t, out.Record)
+		records[id] = out.Record
+	}
+	return records
+}
+
+func hashMessage(msg string) []byte {
+	messageHash := make([]byte, 64)
+	sha3.ShakeSum128(messageHash, []byte(msg))
+	return messageHash
+}
+
+// runOnlineSign drives the online round to completion for every given record
+// and returns each signer's resulting Signature. onlineOutput.Finalize already
+// verifies the combined signature against the group's public key before
+// returning it, so a failure here surfaces as a require.NoError failure rather
+// than a bad Signature slipping through.
+func runOnlineSign(t *testing.T, configs map[party.ID]*keygen.Config, records map[party.ID]*PresignRecord, hash []byte) map[party.ID]*Signature {
+	t.Helper()
+
+	rounds := make(map[party.ID]round.Round, len(records))
+	for id, record := range records {
+		r, _, err := StartOnlineSign(configs[id], record, hash)()
+		require.NoError(t, err)
+		rounds[id] = r
+	}
+
+	out := make(chan *message.Message, len(rounds)*len(rounds))
+	for id, r := range rounds {
+		next, err := r.Finalize(out)
+		require.NoError(t, err, "party %v failed to finalize online round", id)
+		rounds[id] = next
+	}
+	close(out)
+	for msg := range out {
+		raw, err := proto.Marshal(msg)
+		require.NoError(t, err)
+		for id, r := range rounds {
+			if msg.From == id {
+				continue
+			}
+			if len(msg.To) != 0 && !party.IDSlice(msg.To).Contains(id) {
+				continue
+			}
+			var m message.Message
+			require.NoError(t, proto.Unmarshal(raw, &m))
+			content := r.MessageContent()
+			require.NoError(t, m.UnmarshalContent(content))
+			require.NoError(t, r.ProcessMessage(m.From, content))
+		}
+	}
+
+	signatures := make(map[party.ID]*Signature, len(rounds))
+	for id, r := range rounds {
+		out, ok := r.(*onlineOutput)
+		require.True(t, ok, "party %v did not reach onlineOutput", id)
+		_, err := out.Finalize(nil)
+		require.NoError(t, err, "party %v failed to finalize signature", id)
+		require.NotNil(t, out.Signature)
+		signatures[id] = out.Signature
+	}
+	return signatures
+}
+
+// TestPresignThenOnlineSign covers scenario (a): generate presign records ahead
+// of time, round-trip them through Marshal/Unmarshal as persistence would, then
+// sign several distinct messages, one record each.
+func TestPresignThenOnlineSign(t *testing.T) {
+	configs := keygen.FakeData(3, 1, mrand.New(mrand.NewSource(7)))
+	signers := make(party.IDSlice, 0, 2)
+	for id := range configs {
+		signers = append(signers, id)
+		if len(signers) == 2 {
+			break
+		}
+	}
+
+	messages := []string{"first", "second", "third"}
+	batches := make([]map[party.ID]*PresignRecord, 0, len(messages))
+	for range messages {
+		records := runPresign(t, configs, signers, nil)
+
+		persisted := make(map[party.ID]*PresignRecord, len(signers))
+		for id, record := range records {
+			data, err := record.Marshal()
+			require.NoError(t, err)
+			var restored PresignRecord
+			require.NoError(t, restored.Unmarshal(data))
+			persisted[id] = &restored
+		}
+		batches = append(batches, persisted)
+	}
+
+	for i, msg := range messages {
+		hash := hashMessage(msg)
+		signatures := runOnlineSign(t, configs, batches[i], hash)
+		for id, sig := range signatures {
+			assert.True(t, sig.Verify(configs[id].PublicPoint(), hash), "party %v produced a signature that doesn't verify for %q", id, msg)
+		}
+	}
+}
+
+// TestPresignRecordSingleUse covers scenario (b): reusing a record to sign a
+// second message must fail with a distinct sentinel error.
+func TestPresignRecordSingleUse(t *testing.T) {
+	configs := keygen.FakeData(3, 1, mrand.New(mrand.NewSource(8)))
+	signers := make(party.IDSlice, 0, 2)
+	for id := range configs {
+		signers = append(signers, id)
+		if len(signers) == 2 {
+			break
+		}
+	}
+
+	records := runPresign(t, configs, signers, nil)
+	id := signers[0]
+	record := records[id]
+
+	signatures := runOnlineSign(t, configs, records, hashMessage("only once"))
+	assert.True(t, signatures[id].Verify(configs[id].PublicPoint(), hashMessage("only once")))
+	assert.True(t, record.Consumed())
+
+	_, _, err := StartOnlineSign(configs[id], record, hashMessage("again"))()
+	assert.ErrorIs(t, err, ErrPresignConsumed)
+}
+
+// TestPresignRecordSSIDBinding covers scenario (c): a record generated under one
+// committee must not be usable against a Config from a different committee.
+func TestPresignRecordSSIDBinding(t *testing.T) {
+	committeeA := keygen.FakeData(3, 1, mrand.New(mrand.NewSource(9)))
+	committeeB := keygen.FakeData(3, 1, mrand.New(mrand.NewSource(10)))
+
+	signersA := make(party.IDSlice, 0, 2)
+	for id := range committeeA {
+		signersA = append(signersA, id)
+		if len(signersA) == 2 {
+			break
+		}
+	}
+
+	records := runPresign(t, committeeA, signersA, nil)
+	recordFromA := records[signersA[0]]
+
+	var configFromB *keygen.Config
+	for _, cfg := range committeeB {
+		configFromB = cfg
+		break
+	}
+
+	_, _, err := StartOnlineSign(configFromB, recordFromA, hashMessage("mismatched"))()
+	assert.ErrorIs(t, err, ErrPresignWrongCommittee)
+}